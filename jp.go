@@ -1,17 +1,15 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
-	"sort"
 
 	"github.com/codegangsta/cli"
-	"github.com/fatih/color"
 	"github.com/jmespath/go-jmespath"
-	"github.com/nwidger/jsoncolor"
+	"github.com/mattn/go-isatty"
+	"github.com/wryun/jp/pkg/jp"
 )
 
 const version = "0.1.2"
@@ -20,17 +18,25 @@ func main() {
 	app := cli.NewApp()
 	app.Name = "jp"
 	app.Version = version
-	app.Usage = "jp [<options>] <expression>"
+	app.Usage = "jp [<options>] <expression> [<expression>...]"
+	app.Description = "jp evaluates a pipeline of JMESPath expressions against JSON, YAML or gron input.\n\n" +
+		"   In addition to the built-in JMESPath functions, expressions may call\n" +
+		"   env('NAME'), now() and uuid(), which are expanded to a literal value\n" +
+		"   before the expression is parsed. There is currently no general\n" +
+		"   mechanism for registering custom functions (go-jmespath, the\n" +
+		"   underlying library, doesn't expose one) or a --plugin flag, so\n" +
+		"   functions needing runtime arguments - base64_encode, base64_decode,\n" +
+		"   regex_match - are not available."
 	app.Author = ""
 	app.Email = ""
 	app.Flags = []cli.Flag{
 		cli.StringFlag{
 			Name:  "filename, f",
-			Usage: "Read input JSON from a file instead of stdin.",
+			Usage: "Read input from a file or http(s):// URL instead of stdin.",
 		},
-		cli.StringFlag{
+		cli.StringSliceFlag{
 			Name:  "expr-file, e",
-			Usage: "Read JMESPath expression from the specified file.",
+			Usage: "Read a JMESPath expression from the specified file. May be repeated; combined with any positional expressions into a single left-to-right pipeline.",
 		},
 		cli.StringFlag{
 			Name:  "color, c",
@@ -46,6 +52,34 @@ func main() {
 			Name:  "stream, s",
 			Usage: "Parse JSON elements until the input stream is exhausted (rather than just the first).",
 		},
+		cli.BoolFlag{
+			Name:  "gron, g",
+			Usage: "Shorthand for --output=gron: print the result as a flat sequence of assignments (one per line) instead of pretty-printed JSON.",
+		},
+		cli.StringFlag{
+			Name:  "output, o",
+			Usage: "Set the output format: pretty, ndjson, raw, or gron. Defaults to pretty, or to ndjson when --stream is set and stdout isn't a tty.",
+		},
+		cli.BoolFlag{
+			Name:  "slurp, a",
+			Usage: "Collect all inputs (see --stream) into a single array before evaluating the expression pipeline.",
+		},
+		cli.BoolFlag{
+			Name:  "ungron, G",
+			Usage: "Read gron-style assignments (as produced by --gron) from the input and reconstruct the JSON value before evaluating the expression.",
+		},
+		cli.BoolFlag{
+			Name:  "yaml, y",
+			Usage: "Parse input as YAML instead of JSON. Auto-detected when --filename ends in .yaml or .yml.",
+		},
+		cli.BoolFlag{
+			Name:  "output-yaml",
+			Usage: "Print the result as YAML instead of colored JSON.",
+		},
+		cli.BoolFlag{
+			Name:  "null-input, n",
+			Usage: "Do not read stdin; start the expression pipeline from null. Useful when the first expression constructs data from scratch.",
+		},
 		cli.BoolFlag{
 			Name:  "ast",
 			Usage: "Only print the AST of the parsed expression.  Do not rely on this output, only useful for debugging purposes.",
@@ -67,50 +101,41 @@ func errMsg(msg string, a ...interface{}) int {
 }
 
 func runMain(c *cli.Context) int {
-	var expression string
-	if c.String("expr-file") != "" {
-		byteExpr, err := ioutil.ReadFile(c.String("expr-file"))
-		expression = string(byteExpr)
+	var expressions []string
+	for _, exprFile := range c.StringSlice("expr-file") {
+		byteExpr, err := ioutil.ReadFile(exprFile)
 		if err != nil {
 			return errMsg("Error opening expression file: %s", err)
 		}
-	} else {
-		if len(c.Args()) == 0 {
-			return errMsg("Must provide at least one argument.")
-		}
-		expression = c.Args()[0]
+		expressions = append(expressions, string(byteExpr))
 	}
-	// Unfortunately, there's a global setting in the underlying library
-	// which we have to toggle here...
-	switch c.String("color") {
-	case "always":
-		color.NoColor = false
-	case "auto":
-		// this is the default in the library
-	case "never":
-		color.NoColor = true
-	default:
-		return errMsg("Invalid color specification. Must use always/auto/never")
+	expressions = append(expressions, c.Args()...)
+	if len(expressions) == 0 {
+		return errMsg("Must provide at least one expression.")
 	}
+
 	if c.Bool("ast") {
 		parser := jmespath.NewParser()
-		parsed, err := parser.Parse(expression)
-		if err != nil {
-			if syntaxError, ok := err.(jmespath.SyntaxError); ok {
-				return errMsg("%s\n%s\n",
-					syntaxError,
-					syntaxError.HighlightLocation())
+		for i, expression := range expressions {
+			parsed, err := parser.Parse(expression)
+			if err != nil {
+				if syntaxError, ok := err.(jmespath.SyntaxError); ok {
+					return errMsg("stage %d: %s\n%s\n", i+1, syntaxError, syntaxError.HighlightLocation())
+				}
+				return errMsg("stage %d: %s", i+1, err)
 			}
-			return errMsg("%s", err)
+			fmt.Println("")
+			fmt.Printf("%s\n", parsed)
 		}
-		fmt.Println("")
-		fmt.Printf("%s\n", parsed)
 		return 0
 	}
 
+	filename := c.String("filename")
 	var inputStream io.Reader
-	if c.String("filename") != "" {
-		f, err := os.Open(c.String("filename"))
+	if c.Bool("null-input") {
+		inputStream = nil
+	} else if filename != "" {
+		f, err := jp.OpenInput(filename)
 		if err != nil {
 			return errMsg("Error opening input file: %s", err)
 		}
@@ -119,100 +144,34 @@ func runMain(c *cli.Context) int {
 		inputStream = os.Stdin
 	}
 
-	newlineNumberReader := NewLineNumberReader(inputStream)
-	jsonParser := json.NewDecoder(newlineNumberReader)
-
-	for {
-		var input interface{}
-		if err := jsonParser.Decode(&input); err == io.EOF {
-			break
-		} else if err != nil {
-			syntaxError, ok := err.(*json.SyntaxError)
-			if ok && syntaxError.Offset == int64(int(syntaxError.Offset)) {
-				line, char := newlineNumberReader.ConvertOffset(int(syntaxError.Offset))
-				errMsg("Error parsing input json: %s (line: %d, char: %d)\n",
-					syntaxError, line, char)
-			} else {
-				errMsg("Error parsing input json: %s", err)
-			}
-			return 2
-		}
-
-		result, err := jmespath.Search(expression, input)
-		if err != nil {
-			if syntaxError, ok := err.(jmespath.SyntaxError); ok {
-				return errMsg("%s\n%s\n",
-					syntaxError,
-					syntaxError.HighlightLocation())
-			}
-			return errMsg("Error evaluating JMESPath expression: %s", err)
-		}
-		converted, isString := result.(string)
-		if c.Bool("unquoted") && isString {
-			os.Stdout.WriteString(converted)
-		} else {
-			var toJSON []byte
-			var err error
-			if color.NoColor {
-				// avoid doing the extra processing in jsoncolor
-				toJSON, err = json.MarshalIndent(result, "", "  ")
-			} else {
-				toJSON, err = jsoncolor.MarshalIndent(result, "", "  ")
-			}
-			if err != nil {
-				errMsg("Error marshalling result to JSON: %s\n", err)
-				return 3
-			}
-			os.Stdout.Write(toJSON)
-		}
-		os.Stdout.WriteString("\n")
-		if !c.Bool("stream") {
-			break
-		}
-	}
-	return 0
-}
-
-type LineNumberReader struct {
-	actualReader     io.Reader
-	newlinePositions []int
-	bytesRead        int
-}
-
-func NewLineNumberReader(actualReader io.Reader) *LineNumberReader {
-	return &LineNumberReader{
-		actualReader: actualReader,
-	}
-}
-
-func (lnr *LineNumberReader) Read(p []byte) (n int, err error) {
-	n, err = lnr.actualReader.Read(p)
-
-	if err != nil || n == 0 {
-		return
+	output := c.String("output")
+	switch {
+	case c.Bool("gron"):
+		output = "gron"
+	case output != "":
+		// explicitly requested
+	case c.Bool("stream") && !isatty.IsTerminal(os.Stdout.Fd()):
+		output = "ndjson"
+	default:
+		output = "pretty"
 	}
 
-	for i, v := range p {
-		if i >= n {
-			return
-		}
-
-		if v == '\n' {
-			// add 1 so we record the position of the first character, not the '\n'
-			lnr.newlinePositions = append(lnr.newlinePositions, lnr.bytesRead+i+1)
-		}
+	opts := jp.Options{
+		Expressions: expressions,
+		Filename:    filename,
+		Color:       c.String("color"),
+		Output:      output,
+		Unquoted:    c.Bool("unquoted"),
+		Stream:      c.Bool("stream"),
+		Slurp:       c.Bool("slurp"),
+		Ungron:      c.Bool("ungron"),
+		YAML:        c.Bool("yaml"),
+		OutputYAML:  c.Bool("output-yaml"),
+		NullInput:   c.Bool("null-input"),
 	}
 
-	lnr.bytesRead = lnr.bytesRead + n
-	return
-}
-
-func (lnr *LineNumberReader) ConvertOffset(offset int) (linePos int, charPos int) {
-	index := sort.SearchInts(lnr.newlinePositions, offset)
-	// Humans are 1 indexed...
-	if index == 0 {
-		return 1, offset
-	} else {
-		return index + 1, offset - lnr.newlinePositions[index-1]
+	if err := jp.NewRunner().Search(inputStream, os.Stdout, opts); err != nil {
+		return errMsg("%s", err)
 	}
+	return 0
 }
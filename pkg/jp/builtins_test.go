@@ -0,0 +1,63 @@
+package jp
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+func TestExpandBuiltinCallsEnv(t *testing.T) {
+	os.Setenv("JP_TEST_BUILTIN_ENV", "hello")
+	defer os.Unsetenv("JP_TEST_BUILTIN_ENV")
+
+	expanded, err := expandBuiltinCalls("env('JP_TEST_BUILTIN_ENV')")
+	if err != nil {
+		t.Fatalf("expandBuiltinCalls: %s", err)
+	}
+	got, err := jmespath.Search(expanded, nil)
+	if err != nil {
+		t.Fatalf("jmespath.Search(%q): %s", expanded, err)
+	}
+	if got != "hello" {
+		t.Fatalf("got %#v, want %q", got, "hello")
+	}
+}
+
+func TestExpandBuiltinCallsNow(t *testing.T) {
+	expanded, err := expandBuiltinCalls("now()")
+	if err != nil {
+		t.Fatalf("expandBuiltinCalls: %s", err)
+	}
+	got, err := jmespath.Search(expanded, nil)
+	if err != nil {
+		t.Fatalf("jmespath.Search(%q): %s", expanded, err)
+	}
+	s, ok := got.(string)
+	if !ok {
+		t.Fatalf("got %#v, want a string", got)
+	}
+	if !regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T`).MatchString(s) {
+		t.Fatalf("got %q, want an RFC3339 timestamp", s)
+	}
+}
+
+func TestExpandBuiltinCallsUUID(t *testing.T) {
+	expanded, err := expandBuiltinCalls("uuid()")
+	if err != nil {
+		t.Fatalf("expandBuiltinCalls: %s", err)
+	}
+	got, err := jmespath.Search(expanded, nil)
+	if err != nil {
+		t.Fatalf("jmespath.Search(%q): %s", expanded, err)
+	}
+	s, ok := got.(string)
+	if !ok {
+		t.Fatalf("got %#v, want a string", got)
+	}
+	uuidRE := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !uuidRE.MatchString(s) {
+		t.Fatalf("got %q, not a version-4 uuid", s)
+	}
+}
@@ -0,0 +1,256 @@
+package jp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Colors used when rendering gron output: blue identifier keys, white
+// punctuation, green strings (and bracketed keys), magenta numbers, and
+// yellow booleans and null.
+var (
+	gronKeyColor    = color.New(color.FgBlue)
+	gronPuncColor   = color.New(color.FgWhite)
+	gronStringColor = color.New(color.FgGreen)
+	gronNumberColor = color.New(color.FgMagenta)
+	gronBoolColor   = color.New(color.FgYellow)
+)
+
+var identifierRE = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*$`)
+
+// ansiEscapeRE matches the SGR escape sequences fatih/color emits, so
+// gronRead can parse gron output that was colorized (e.g. produced by
+// `jp -g --color always`) regardless of this process's own --color
+// setting.
+var ansiEscapeRE = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func stripANSI(s string) string {
+	return ansiEscapeRE.ReplaceAllString(s, "")
+}
+
+// gronWrite walks value and writes one JavaScript-style assignment
+// statement per line to w, rooted at the given variable name (by
+// convention "json").
+func gronWrite(w io.Writer, root string, value interface{}) error {
+	return gronValue(w, root, value)
+}
+
+func gronValue(w io.Writer, path string, value interface{}) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if err := gronAssign(w, path, "{}"); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := gronValue(w, path+gronKeySuffix(k), v[k]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if err := gronAssign(w, path, "[]"); err != nil {
+			return err
+		}
+		for i, item := range v {
+			if err := gronValue(w, fmt.Sprintf("%s%s", path, gronIndexSuffix(i)), item); err != nil {
+				return err
+			}
+		}
+	case string:
+		if err := gronAssign(w, path, gronStringColor.Sprint(strconv.Quote(v))); err != nil {
+			return err
+		}
+	case float64:
+		if err := gronAssign(w, path, gronNumberColor.Sprint(formatGronNumber(v))); err != nil {
+			return err
+		}
+	case bool:
+		if err := gronAssign(w, path, gronBoolColor.Sprint(strconv.FormatBool(v))); err != nil {
+			return err
+		}
+	case nil:
+		if err := gronAssign(w, path, gronBoolColor.Sprint("null")); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("gron: unsupported value type %T", value)
+	}
+	return nil
+}
+
+func gronAssign(w io.Writer, path, value string) error {
+	_, err := fmt.Fprintf(w, "%s %s %s%s\n", path, gronPuncColor.Sprint("="), value, gronPuncColor.Sprint(";"))
+	return err
+}
+
+func gronKeySuffix(key string) string {
+	if identifierRE.MatchString(key) {
+		return gronPuncColor.Sprint(".") + gronKeyColor.Sprint(key)
+	}
+	return gronPuncColor.Sprint("[") + gronStringColor.Sprint(strconv.Quote(key)) + gronPuncColor.Sprint("]")
+}
+
+func gronIndexSuffix(index int) string {
+	return gronPuncColor.Sprint("[") + strconv.Itoa(index) + gronPuncColor.Sprint("]")
+}
+
+func formatGronNumber(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// pathSegment is one step ("[0]" or ".foo") of a gron assignment's
+// left hand side.
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+var pathSegmentRE = regexp.MustCompile(`\.([a-zA-Z_$][a-zA-Z0-9_$]*)|\[(\d+)\]|\["((?:[^"\\]|\\.)*)"\]`)
+
+// rootIdentifierRE matches just the leading identifier of a path (e.g.
+// "json" in "json.list[0]"), unlike identifierRE which requires the
+// whole string to be an identifier.
+var rootIdentifierRE = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*`)
+
+// scanGronPath consumes the path portion from the start of line (the
+// root identifier followed by any number of ".foo"/"[0]"/["key"]
+// segments) and returns the segments alongside whatever of line is left
+// unconsumed (expected to be " = <value>"). Scanning the path itself,
+// rather than splitting the line on the first "=", means an "="
+// embedded in a bracketed key (e.g. json["x=y"]) can't be mistaken for
+// the assignment operator.
+func scanGronPath(line string) ([]pathSegment, string, error) {
+	root := rootIdentifierRE.FindString(line)
+	if root == "" {
+		return nil, "", fmt.Errorf("ungron: invalid line %q", line)
+	}
+	rest := line[len(root):]
+
+	var segments []pathSegment
+	pos := 0
+	for pos < len(rest) {
+		loc := pathSegmentRE.FindStringSubmatchIndex(rest[pos:])
+		if loc == nil || loc[0] != 0 {
+			break
+		}
+		match := pathSegmentRE.FindStringSubmatch(rest[pos:])
+		switch {
+		case match[1] != "":
+			segments = append(segments, pathSegment{key: match[1]})
+		case match[2] != "":
+			index, err := strconv.Atoi(match[2])
+			if err != nil {
+				return nil, "", fmt.Errorf("ungron: invalid array index in %q: %s", line, err)
+			}
+			segments = append(segments, pathSegment{index: index, isIndex: true})
+		default:
+			unquoted, err := strconv.Unquote(`"` + match[3] + `"`)
+			if err != nil {
+				return nil, "", fmt.Errorf("ungron: invalid key in %q: %s", line, err)
+			}
+			segments = append(segments, pathSegment{key: unquoted})
+		}
+		pos += loc[1]
+	}
+	return segments, rest[pos:], nil
+}
+
+// gronRead reads gron-style assignment statements from r (as produced
+// by gronWrite) and reconstructs the single JSON value they describe.
+func gronRead(r io.Reader) (interface{}, error) {
+	scanner := bufio.NewScanner(r)
+	var root interface{}
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripANSI(scanner.Text()))
+		if line == "" {
+			continue
+		}
+		line = strings.TrimSuffix(line, ";")
+
+		segments, remainder, err := scanGronPath(line)
+		if err != nil {
+			return nil, err
+		}
+		remainder = strings.TrimSpace(remainder)
+		rhs, ok := strings.CutPrefix(remainder, "=")
+		if !ok {
+			return nil, fmt.Errorf("ungron: invalid line %q", line)
+		}
+		rhs = strings.TrimSpace(rhs)
+
+		var val interface{}
+		// gronWrite quotes strings with strconv.Quote, whose escapes
+		// (e.g. \x01) are not all valid JSON.
+		if s, uerr := strconv.Unquote(rhs); uerr == nil && strings.HasPrefix(rhs, `"`) {
+			val = s
+		} else if err := json.Unmarshal([]byte(rhs), &val); err != nil {
+			return nil, fmt.Errorf("ungron: invalid value in %q: %s", line, err)
+		}
+
+		if len(segments) == 0 {
+			root = val
+			continue
+		}
+		root, err = gronSet(root, segments, val)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func gronSet(node interface{}, segments []pathSegment, val interface{}) (interface{}, error) {
+	seg := segments[0]
+	rest := segments[1:]
+
+	if seg.isIndex {
+		arr, ok := node.([]interface{})
+		if !ok {
+			arr = []interface{}{}
+		}
+		for len(arr) <= seg.index {
+			arr = append(arr, nil)
+		}
+		if len(rest) == 0 {
+			arr[seg.index] = val
+			return arr, nil
+		}
+		child, err := gronSet(arr[seg.index], rest, val)
+		if err != nil {
+			return nil, err
+		}
+		arr[seg.index] = child
+		return arr, nil
+	}
+
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		m = map[string]interface{}{}
+	}
+	if len(rest) == 0 {
+		m[seg.key] = val
+		return m, nil
+	}
+	child, err := gronSet(m[seg.key], rest, val)
+	if err != nil {
+		return nil, err
+	}
+	m[seg.key] = child
+	return m, nil
+}
@@ -0,0 +1,69 @@
+package jp
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestGronRoundTrip(t *testing.T) {
+	orig := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = orig }()
+
+	cases := map[string]interface{}{
+		"scalar": "hello",
+		"nested object and array": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{"id": float64(42), "ok": true},
+				nil,
+			},
+			"name": "jp",
+		},
+		"bracketed numeric-string key": map[string]interface{}{
+			"123": "numeric key",
+		},
+		"bracketed key with spaces": map[string]interface{}{
+			"weird key": true,
+		},
+		"empty object and array": map[string]interface{}{
+			"obj": map[string]interface{}{},
+			"arr": []interface{}{},
+		},
+		"bracketed key containing an equals sign": map[string]interface{}{
+			"x=y": "z",
+		},
+	}
+
+	for name, value := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := gronWrite(&buf, "json", value); err != nil {
+				t.Fatalf("gronWrite: %s", err)
+			}
+
+			got, err := gronRead(&buf)
+			if err != nil {
+				t.Fatalf("gronRead: %s", err)
+			}
+			if !reflect.DeepEqual(got, value) {
+				t.Fatalf("round trip mismatch: got %#v, want %#v", got, value)
+			}
+		})
+	}
+}
+
+func TestGronReadStripsANSI(t *testing.T) {
+	input := "json\x1b[37m.\x1b[0m\x1b[34mfoo\x1b[0m \x1b[37m=\x1b[0m \x1b[32m\"bar\"\x1b[0m\x1b[37m;\x1b[0m\n"
+
+	got, err := gronRead(bytes.NewBufferString(input))
+	if err != nil {
+		t.Fatalf("gronRead: %s", err)
+	}
+	want := map[string]interface{}{"foo": "bar"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+package jp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// OpenInput returns a reader for filename, which may be a local path or
+// an http(s):// URL.
+func OpenInput(filename string) (io.Reader, error) {
+	if strings.HasPrefix(filename, "http://") || strings.HasPrefix(filename, "https://") {
+		resp, err := http.Get(filename)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode >= 400 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s: %s", filename, resp.Status)
+		}
+		return resp.Body, nil
+	}
+	return os.Open(filename)
+}
+
+// looksLikeYAML reports whether filename's extension suggests YAML
+// content, for auto-detecting --yaml when it wasn't passed explicitly.
+func looksLikeYAML(filename string) bool {
+	return strings.HasSuffix(filename, ".yaml") || strings.HasSuffix(filename, ".yml")
+}
+
+// normalizeYAML converts the map[interface{}]interface{} values
+// produced by yaml.Unmarshal into map[string]interface{} (recursively),
+// and widens int/int64/uint64 (yaml.v2's resolver uses uint64 for
+// integers beyond math.MaxInt64) to float64, so the result can be used
+// with JMESPath (whose interpreter expects JSON's numeric type) and
+// encoding/json.
+func normalizeYAML(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			strKey, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("yaml: non-string map key %v (%T)", key, key)
+			}
+			normalized, err := normalizeYAML(val)
+			if err != nil {
+				return nil, err
+			}
+			m[strKey] = normalized
+		}
+		return m, nil
+	case []interface{}:
+		for i, item := range v {
+			normalized, err := normalizeYAML(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = normalized
+		}
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	default:
+		return value, nil
+	}
+}
+
+// yamlDecoder decodes successive YAML documents (separated by "---")
+// from r, normalizing each one for use with JMESPath.
+type yamlDecoder struct {
+	decoder *yaml.Decoder
+}
+
+func newYAMLDecoder(r io.Reader) *yamlDecoder {
+	return &yamlDecoder{decoder: yaml.NewDecoder(r)}
+}
+
+func (d *yamlDecoder) Decode(out *interface{}) error {
+	var raw interface{}
+	if err := d.decoder.Decode(&raw); err != nil {
+		return err
+	}
+	normalized, err := normalizeYAML(raw)
+	if err != nil {
+		return err
+	}
+	*out = normalized
+	return nil
+}
@@ -0,0 +1,130 @@
+package jp
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOpenInputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.json")
+	if err := os.WriteFile(path, []byte(`{"a":1}`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	r, err := OpenInput(path)
+	if err != nil {
+		t.Fatalf("OpenInput: %s", err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %s", err)
+	}
+	if string(got) != `{"a":1}` {
+		t.Fatalf("got %q, want %q", got, `{"a":1}`)
+	}
+}
+
+func TestOpenInputURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"a":1}`))
+	}))
+	defer server.Close()
+
+	r, err := OpenInput(server.URL)
+	if err != nil {
+		t.Fatalf("OpenInput: %s", err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %s", err)
+	}
+	if string(got) != `{"a":1}` {
+		t.Fatalf("got %q, want %q", got, `{"a":1}`)
+	}
+}
+
+func TestOpenInputURLError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if _, err := OpenInput(server.URL); err == nil {
+		t.Fatal("expected an error for a 404 response")
+	}
+}
+
+func TestLooksLikeYAML(t *testing.T) {
+	cases := map[string]bool{
+		"config.yaml": true,
+		"config.yml":  true,
+		"config.json": false,
+		"":            false,
+	}
+	for filename, want := range cases {
+		if got := looksLikeYAML(filename); got != want {
+			t.Errorf("looksLikeYAML(%q) = %v, want %v", filename, got, want)
+		}
+	}
+}
+
+func TestNormalizeYAML(t *testing.T) {
+	cases := []struct {
+		name  string
+		input interface{}
+		want  interface{}
+	}{
+		{
+			name:  "int widened to float64",
+			input: map[interface{}]interface{}{"replicas": int(3)},
+			want:  map[string]interface{}{"replicas": float64(3)},
+		},
+		{
+			name:  "int64 widened to float64",
+			input: map[interface{}]interface{}{"port": int64(8080)},
+			want:  map[string]interface{}{"port": float64(8080)},
+		},
+		{
+			name:  "uint64 widened to float64",
+			input: map[interface{}]interface{}{"big": uint64(18446744073709551615)},
+			want:  map[string]interface{}{"big": float64(18446744073709551615)},
+		},
+		{
+			name: "nested maps and arrays",
+			input: map[interface{}]interface{}{
+				"list": []interface{}{
+					map[interface{}]interface{}{"id": int(1)},
+				},
+			},
+			want: map[string]interface{}{
+				"list": []interface{}{
+					map[string]interface{}{"id": float64(1)},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := normalizeYAML(tc.input)
+			if err != nil {
+				t.Fatalf("normalizeYAML: %s", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeYAMLNonStringKey(t *testing.T) {
+	_, err := normalizeYAML(map[interface{}]interface{}{1: "bad"})
+	if err == nil {
+		t.Fatal("expected an error for a non-string map key")
+	}
+}
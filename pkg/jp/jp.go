@@ -0,0 +1,226 @@
+// Package jp implements the query engine behind the jp command line
+// tool: decoding input (JSON, YAML, gron), evaluating a pipeline of
+// JMESPath expressions, and rendering the result. It is split out from
+// the jp command (package main at the repository root) so other Go
+// programs can embed the same behaviour.
+package jp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/jmespath/go-jmespath"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Options controls how Runner.Search decodes its input, evaluates the
+// expression pipeline, and renders its output.
+type Options struct {
+	// Expressions is the pipeline of JMESPath expressions to evaluate
+	// left to right; the result of each becomes the input to the next.
+	Expressions []string
+	// Filename is only used to auto-detect YAML input by extension; it
+	// plays no part in how the input is actually read.
+	Filename string
+	// Color is one of "auto" (the default), "always" or "never".
+	Color string
+	// Output is one of "pretty" (the default), "ndjson", "raw" or
+	// "gron"; it controls how each result is rendered.
+	Output     string
+	Unquoted   bool
+	Stream     bool
+	Slurp      bool
+	Ungron     bool
+	YAML       bool
+	OutputYAML bool
+	NullInput  bool
+}
+
+// Runner evaluates expression pipelines against decoded input.
+type Runner struct{}
+
+// NewRunner returns a Runner ready to use. It holds no state of its own;
+// all configuration is passed per call via Options.
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+// Search decodes documents from in according to opts, evaluates
+// opts.Expressions against each one in turn, and writes the rendered
+// result(s) to out.
+func (r *Runner) Search(in io.Reader, out io.Writer, opts Options) error {
+	if len(opts.Expressions) == 0 {
+		return fmt.Errorf("must provide at least one expression")
+	}
+	// Unfortunately, there's a global setting in the underlying library
+	// which we have to toggle here...
+	switch opts.Color {
+	case "always":
+		color.NoColor = false
+	case "auto", "":
+		// this is the default in the library
+	case "never":
+		color.NoColor = true
+	default:
+		return fmt.Errorf("invalid color specification: must use always/auto/never")
+	}
+
+	if opts.NullInput {
+		return evalAndPrint(out, opts, nil)
+	}
+
+	if opts.Ungron {
+		input, err := gronRead(in)
+		if err != nil {
+			return fmt.Errorf("error parsing ungron input: %s", err)
+		}
+		return evalAndPrint(out, opts, input)
+	}
+
+	if opts.YAML || looksLikeYAML(opts.Filename) {
+		yamlParser := newYAMLDecoder(in)
+		decode := func() (interface{}, error) {
+			var input interface{}
+			err := yamlParser.Decode(&input)
+			return input, err
+		}
+		return decodeLoop(out, opts, decode, func(err error) error {
+			return fmt.Errorf("error parsing input yaml: %s", err)
+		})
+	}
+
+	newlineNumberReader := NewLineNumberReader(stripJSONTextSequences(in))
+	jsonParser := json.NewDecoder(newlineNumberReader)
+	decode := func() (interface{}, error) {
+		var input interface{}
+		err := jsonParser.Decode(&input)
+		return input, err
+	}
+	return decodeLoop(out, opts, decode, func(err error) error {
+		syntaxError, ok := err.(*json.SyntaxError)
+		if ok && syntaxError.Offset == int64(int(syntaxError.Offset)) {
+			line, char := newlineNumberReader.ConvertOffset(int(syntaxError.Offset))
+			return fmt.Errorf("error parsing input json: %s (line: %d, char: %d)", syntaxError, line, char)
+		}
+		return fmt.Errorf("error parsing input json: %s", err)
+	})
+}
+
+// decodeLoop repeatedly calls decode and, per opts, either evaluates and
+// prints each document as it arrives or (with opts.Slurp) collects them
+// all into a single array first. wrapErr turns a non-EOF decode error
+// into the error decodeLoop should return.
+func decodeLoop(out io.Writer, opts Options, decode func() (interface{}, error), wrapErr func(error) error) error {
+	var slurped []interface{}
+	for {
+		input, err := decode()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return wrapErr(err)
+		}
+
+		if opts.Slurp {
+			slurped = append(slurped, input)
+			continue
+		}
+
+		if err := evalAndPrint(out, opts, input); err != nil {
+			return err
+		}
+		if !opts.Stream {
+			break
+		}
+	}
+	if opts.Slurp {
+		return evalAndPrint(out, opts, slurped)
+	}
+	return nil
+}
+
+func evalAndPrint(out io.Writer, opts Options, input interface{}) error {
+	result := input
+	for i, expression := range opts.Expressions {
+		expanded, err := expandBuiltinCalls(expression)
+		if err != nil {
+			return fmt.Errorf("stage %d: %s", i+1, err)
+		}
+		result, err = jmespath.Search(expanded, result)
+		if err != nil {
+			if syntaxError, ok := err.(jmespath.SyntaxError); ok {
+				return fmt.Errorf("stage %d: %s\n%s", i+1, syntaxError, syntaxError.HighlightLocation())
+			}
+			return fmt.Errorf("stage %d: error evaluating JMESPath expression: %s", i+1, err)
+		}
+	}
+
+	if opts.OutputYAML {
+		toYAML, err := yaml.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("error marshalling result to yaml: %s", err)
+		}
+		_, err = out.Write(toYAML)
+		return err
+	}
+
+	switch opts.Output {
+	case "gron":
+		return gronWrite(out, "json", result)
+	case "ndjson":
+		if opts.Unquoted {
+			return writeRaw(out, result)
+		}
+		return writeCompactJSON(out, result)
+	case "raw":
+		return writeRaw(out, result)
+	default:
+		return writePretty(out, opts, result)
+	}
+}
+
+type LineNumberReader struct {
+	actualReader     io.Reader
+	newlinePositions []int
+	bytesRead        int
+}
+
+func NewLineNumberReader(actualReader io.Reader) *LineNumberReader {
+	return &LineNumberReader{
+		actualReader: actualReader,
+	}
+}
+
+func (lnr *LineNumberReader) Read(p []byte) (n int, err error) {
+	n, err = lnr.actualReader.Read(p)
+
+	if err != nil || n == 0 {
+		return
+	}
+
+	for i, v := range p {
+		if i >= n {
+			return
+		}
+
+		if v == '\n' {
+			// add 1 so we record the position of the first character, not the '\n'
+			lnr.newlinePositions = append(lnr.newlinePositions, lnr.bytesRead+i+1)
+		}
+	}
+
+	lnr.bytesRead = lnr.bytesRead + n
+	return
+}
+
+func (lnr *LineNumberReader) ConvertOffset(offset int) (linePos int, charPos int) {
+	index := sort.SearchInts(lnr.newlinePositions, offset)
+	// Humans are 1 indexed...
+	if index == 0 {
+		return 1, offset
+	} else {
+		return index + 1, offset - lnr.newlinePositions[index-1]
+	}
+}
@@ -0,0 +1,85 @@
+package jp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func runSearch(t *testing.T, in string, opts Options) string {
+	t.Helper()
+	orig := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = orig }()
+
+	var out bytes.Buffer
+	if err := NewRunner().Search(strings.NewReader(in), &out, opts); err != nil {
+		t.Fatalf("Search: %s", err)
+	}
+	return out.String()
+}
+
+func TestSearchExpressionPipeline(t *testing.T) {
+	got := runSearch(t, `{"a": {"b": 2}}`, Options{
+		Expressions: []string{"a.b", "sum([@, `2`])"},
+	})
+	if got != "4\n" {
+		t.Fatalf("got %q, want %q", got, "4\n")
+	}
+}
+
+func TestSearchYAMLInput(t *testing.T) {
+	got := runSearch(t, "replicas: 3\ncontainerPort: 8080\n", Options{
+		Expressions: []string{"sum([replicas, containerPort])"},
+		YAML:        true,
+	})
+	if got != "8083\n" {
+		t.Fatalf("got %q, want %q", got, "8083\n")
+	}
+}
+
+func TestSearchNDJSONOutput(t *testing.T) {
+	got := runSearch(t, `{"a":1}{"a":2}`, Options{
+		Expressions: []string{"a"},
+		Stream:      true,
+		Output:      "ndjson",
+	})
+	if got != "1\n2\n" {
+		t.Fatalf("got %q, want %q", got, "1\n2\n")
+	}
+}
+
+func TestSearchRawOutput(t *testing.T) {
+	got := runSearch(t, `{"a":"hello"}`, Options{
+		Expressions: []string{"a"},
+		Output:      "raw",
+	})
+	if got != "hello\n" {
+		t.Fatalf("got %q, want %q", got, "hello\n")
+	}
+}
+
+func TestSearchSlurp(t *testing.T) {
+	got := runSearch(t, `{"a":1}{"a":2}{"a":3}`, Options{
+		Expressions: []string{"[].a | sum(@)"},
+		Stream:      true,
+		Slurp:       true,
+		Output:      "ndjson",
+	})
+	if got != "6\n" {
+		t.Fatalf("got %q, want %q", got, "6\n")
+	}
+}
+
+func TestSearchNullInput(t *testing.T) {
+	got := runSearch(t, "", Options{
+		Expressions: []string{"`1`"},
+		NullInput:   true,
+		Output:      "ndjson",
+	})
+	if got != "1\n" {
+		t.Fatalf("got %q, want %q", got, "1\n")
+	}
+}
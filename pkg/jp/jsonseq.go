@@ -0,0 +1,32 @@
+package jp
+
+import "io"
+
+// jsonTextSequenceReader strips the RFC 7464 JSON Text Sequence record
+// separator (0x1E) from the underlying stream so json.Decoder can parse
+// the enclosed records as if they were plain whitespace-separated JSON;
+// the terminating 0x0A of each record is already valid JSON whitespace.
+type jsonTextSequenceReader struct {
+	r io.Reader
+}
+
+// stripJSONTextSequences wraps r so that any RFC 7464 record-separator
+// bytes it contains are transparently discarded.
+func stripJSONTextSequences(r io.Reader) io.Reader {
+	return &jsonTextSequenceReader{r: r}
+}
+
+func (s *jsonTextSequenceReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if n == 0 {
+		return n, err
+	}
+
+	out := p[:0]
+	for _, b := range p[:n] {
+		if b != 0x1E {
+			out = append(out, b)
+		}
+	}
+	return len(out), err
+}
@@ -0,0 +1,43 @@
+package jp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestStripJSONTextSequences(t *testing.T) {
+	input := "\x1e{\"a\":1}\n\x1e{\"b\":2}\n"
+	got, err := ioutil.ReadAll(stripJSONTextSequences(bytes.NewBufferString(input)))
+	if err != nil {
+		t.Fatalf("ReadAll: %s", err)
+	}
+	want := "{\"a\":1}\n{\"b\":2}\n"
+	if string(got) != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestStripJSONTextSequencesWithDecoder(t *testing.T) {
+	input := "\x1e{\"a\":1}\n\x1e{\"b\":2}\n"
+	decoder := json.NewDecoder(stripJSONTextSequences(bytes.NewBufferString(input)))
+
+	var results []interface{}
+	for {
+		var v interface{}
+		err := decoder.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode: %s", err)
+		}
+		results = append(results, v)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+}
@@ -0,0 +1,68 @@
+package jp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+	"github.com/nwidger/jsoncolor"
+)
+
+// writePretty renders result as colored, two-space-indented JSON,
+// unless opts.Unquoted is set and result is itself a string.
+func writePretty(out io.Writer, opts Options, result interface{}) error {
+	converted, isString := result.(string)
+	if opts.Unquoted && isString {
+		if _, err := io.WriteString(out, converted); err != nil {
+			return err
+		}
+	} else {
+		var toJSON []byte
+		var err error
+		if color.NoColor {
+			// avoid doing the extra processing in jsoncolor
+			toJSON, err = json.MarshalIndent(result, "", "  ")
+		} else {
+			toJSON, err = jsoncolor.MarshalIndent(result, "", "  ")
+		}
+		if err != nil {
+			return fmt.Errorf("error marshalling result to json: %s", err)
+		}
+		if _, err := out.Write(toJSON); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(out, "\n")
+	return err
+}
+
+// writeCompactJSON renders result as a single line of JSON, suitable
+// for ndjson/JSON-Lines style streaming output.
+func writeCompactJSON(out io.Writer, result interface{}) error {
+	var toJSON []byte
+	var err error
+	if color.NoColor {
+		toJSON, err = json.Marshal(result)
+	} else {
+		toJSON, err = jsoncolor.Marshal(result)
+	}
+	if err != nil {
+		return fmt.Errorf("error marshalling result to json: %s", err)
+	}
+	if _, err := out.Write(toJSON); err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, "\n")
+	return err
+}
+
+// writeRaw renders string results without quoting, and falls back to
+// writeCompactJSON for everything else (mirroring jq's -r).
+func writeRaw(out io.Writer, result interface{}) error {
+	if s, ok := result.(string); ok {
+		_, err := io.WriteString(out, s+"\n")
+		return err
+	}
+	return writeCompactJSON(out, result)
+}
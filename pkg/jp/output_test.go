@@ -0,0 +1,85 @@
+package jp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestWritePretty(t *testing.T) {
+	orig := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = orig }()
+
+	t.Run("quoted string by default", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writePretty(&buf, Options{}, "hello"); err != nil {
+			t.Fatalf("writePretty: %s", err)
+		}
+		if got, want := buf.String(), "\"hello\"\n"; got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("unquoted string with Unquoted set", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writePretty(&buf, Options{Unquoted: true}, "hello"); err != nil {
+			t.Fatalf("writePretty: %s", err)
+		}
+		if got, want := buf.String(), "hello\n"; got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Unquoted has no effect on non-strings", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writePretty(&buf, Options{Unquoted: true}, map[string]interface{}{"a": float64(1)}); err != nil {
+			t.Fatalf("writePretty: %s", err)
+		}
+		if got, want := buf.String(), "{\n  \"a\": 1\n}\n"; got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+}
+
+func TestWriteCompactJSON(t *testing.T) {
+	orig := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = orig }()
+
+	var buf bytes.Buffer
+	value := map[string]interface{}{"a": float64(1), "b": "two"}
+	if err := writeCompactJSON(&buf, value); err != nil {
+		t.Fatalf("writeCompactJSON: %s", err)
+	}
+	if got, want := buf.String(), "{\"a\":1,\"b\":\"two\"}\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteRaw(t *testing.T) {
+	orig := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = orig }()
+
+	t.Run("string is written unquoted", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeRaw(&buf, "hello"); err != nil {
+			t.Fatalf("writeRaw: %s", err)
+		}
+		if got, want := buf.String(), "hello\n"; got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("non-string falls back to compact json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeRaw(&buf, float64(42)); err != nil {
+			t.Fatalf("writeRaw: %s", err)
+		}
+		if got, want := buf.String(), "42\n"; got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+}